@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	blocks "github.com/ipfs/go-block-format"
+	cid "github.com/ipfs/go-cid"
+
+	"github.com/ipfs/boxo/verifcid"
+)
+
+// AllowlistExchange is an optional interface implemented by exchanges that
+// gate which hash functions and digest lengths they are willing to fetch
+// blocks for, following the pattern used by boxo's BoundedBlockService.
+// Centralizing this check here means blockservice, bitswap clients, and
+// gateways don't each need to reimplement multihash validation.
+type AllowlistExchange interface {
+	// Allowlist returns the set of hash functions this exchange is willing
+	// to fetch blocks for.
+	Allowlist() verifcid.Allowlist
+}
+
+// FetchWithAllowlist validates c against allow before fetching it from f,
+// returning an error instead of reaching the network for a hash that isn't
+// allowed.
+func FetchWithAllowlist(ctx context.Context, f Fetcher, c cid.Cid, allow verifcid.Allowlist) (blocks.Block, error) {
+	if err := verifcid.ValidateCid(allow, c); err != nil {
+		return nil, fmt.Errorf("fetching block: %w", err)
+	}
+	return f.GetBlock(ctx, c)
+}
+
+// NewAllowlistSession wraps f, a Fetcher normally obtained from a nested
+// NewSession call, so that it inherits allow's hash-security checks.
+// AllowlistExchange implementations that also support sessions should use
+// this from their own NewSession method so the returned session Fetcher
+// can't be used to bypass the parent exchange's allowlist, e.g.:
+//
+//	func (e *myExchange) NewSession(ctx context.Context) exchange.Fetcher {
+//		child := e.inner.NewSession(ctx)
+//		return exchange.NewAllowlistSession(child, e.Allowlist())
+//	}
+func NewAllowlistSession(f Fetcher, allow verifcid.Allowlist) Fetcher {
+	return &allowlistFetcher{f: f, allow: allow}
+}
+
+type allowlistFetcher struct {
+	f     Fetcher
+	allow verifcid.Allowlist
+}
+
+func (a *allowlistFetcher) GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	return FetchWithAllowlist(ctx, a.f, c, a.allow)
+}
+
+func (a *allowlistFetcher) GetBlocks(ctx context.Context, cids []cid.Cid) (<-chan blocks.Block, error) {
+	allowed := make([]cid.Cid, 0, len(cids))
+	for _, c := range cids {
+		if err := verifcid.ValidateCid(a.allow, c); err == nil {
+			allowed = append(allowed, c)
+		}
+	}
+	return a.f.GetBlocks(ctx, allowed)
+}
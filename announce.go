@@ -0,0 +1,34 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// Announcer is an optional interface implemented by exchanges that can tell
+// the wider network about blocks a caller already has, without transferring
+// the block data itself -- for example, publishing DHT or IPNI provider
+// records. It is split out from NotifyNewBlocks so that announcing can be
+// requested explicitly, and so exchanges can batch announces instead of
+// making one per block.
+type Announcer interface {
+	// Announce tells the network that the given CIDs are available,
+	// batching the announcement where the underlying exchange supports it.
+	Announce(ctx context.Context, cids ...cid.Cid) error
+
+	// AnnounceProvider tells the network that c is available, requesting
+	// that the provider record be kept for approximately ttl.
+	AnnounceProvider(ctx context.Context, c cid.Cid, ttl time.Duration) error
+}
+
+// AnnounceIfSupported calls Announce on ex if ex implements Announcer, and
+// is a no-op otherwise.
+func AnnounceIfSupported(ctx context.Context, ex Interface, cids ...cid.Cid) error {
+	a, ok := ex.(Announcer)
+	if !ok {
+		return nil
+	}
+	return a.Announce(ctx, cids...)
+}
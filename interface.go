@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"sync/atomic"
 
 	blocks "github.com/ipfs/go-block-format"
 	cid "github.com/ipfs/go-cid"
@@ -15,9 +14,15 @@ import (
 type Interface interface { // type Exchanger interface
 	Fetcher
 
-	// TODO Should callers be concerned with whether the block was made
-	// available on the network?
-	HasBlock(blocks.Block) error
+	// NotifyNewBlocks tells the exchange that new blocks are available and
+	// can be served to other peers. Implementations should match the given
+	// blocks against any pending wantlists in a single pass rather than
+	// requiring callers to notify one block at a time.
+	//
+	// NotifyNewBlocks does not, by itself, announce the blocks to the wider
+	// network (e.g. publishing DHT/IPNI provider records). Exchanges that
+	// support that should implement Announcer.
+	NotifyNewBlocks(ctx context.Context, blocks ...blocks.Block) error
 
 	IsOnline() bool
 
@@ -54,60 +59,3 @@ func (id SessionID) String() string {
 func (id SessionID) IsZero() bool {
 	return id.id == 0
 }
-
-type sessionContextKey struct{}
-type sessionContextValue struct {
-	sesID  SessionID
-	sesCtx context.Context
-}
-
-// NewSession registers a new session with the context. The session will be
-// closed when the passed-in context is canceled.
-//
-// If there's already a session associated with the context, the existing
-// session will be used.
-//
-// This function does not initialize any state, it just reserves a new SessionID
-// associates it with the context.
-func NewSession(ctx context.Context) context.Context {
-	if _, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
-		return ctx
-	}
-	_, ctx = createSession(ctx)
-	return ctx
-}
-
-// last allocated session ID. 0 is _never_ used.
-var lastSessionID uint64
-
-// GetOrCreateSession loads the session from the context, or creates one if
-// there is no associated session.
-//
-// This function also returns the context used to create the session. The
-// session should be stopped when this context is canceled.
-func GetOrCreateSession(ctx context.Context) (SessionID, context.Context) {
-	if s, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
-		return s.sesID, s.sesCtx
-	}
-	return createSession(ctx)
-}
-
-func createSession(ctx context.Context) (SessionID, context.Context) {
-	// Allocate a new session ID
-	id := SessionID{atomic.AddUint64(&lastSessionID, 1)}
-
-	// Create a spot to spot to hold the session information.
-	ctxValue := &sessionContextValue{sesID: id}
-
-	// Derive a new context with this information.
-	ctx = context.WithValue(ctx, sessionContextKey{}, ctxValue)
-
-	// Cyclically reference the session context so the session's context
-	// also references the session.
-	//
-	// We could reference the original context, but that doesn't have the
-	// session attached to it.
-	ctxValue.sesCtx = ctx
-
-	return id, ctx
-}
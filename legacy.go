@@ -0,0 +1,31 @@
+package exchange
+
+import (
+	"context"
+
+	blocks "github.com/ipfs/go-block-format"
+)
+
+// SingleBlockNotifier is implemented by legacy exchanges that only know how
+// to be notified about one new block at a time.
+type SingleBlockNotifier interface {
+	HasBlock(blocks.Block) error
+}
+
+// LegacyNotifier adapts a SingleBlockNotifier to the NotifyNewBlocks
+// signature required by Interface, so existing single-block implementations
+// keep working while they migrate. It calls the wrapped HasBlock once per
+// block, in order, stopping at the first error.
+type LegacyNotifier struct {
+	SingleBlockNotifier
+}
+
+// NotifyNewBlocks implements Interface.
+func (n LegacyNotifier) NotifyNewBlocks(ctx context.Context, blks ...blocks.Block) error {
+	for _, b := range blks {
+		if err := n.HasBlock(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
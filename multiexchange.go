@@ -0,0 +1,197 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	blocks "github.com/ipfs/go-block-format"
+	cid "github.com/ipfs/go-cid"
+)
+
+// ErrNoBackends is returned by a multiFetcher (and so by MultiExchange) when
+// it has no backends to fan a call out to.
+var ErrNoBackends = errors.New("exchange: no backends configured")
+
+// multiFetcher races and multiplexes Fetcher calls across a fixed list of
+// backend Fetchers.
+type multiFetcher struct {
+	fetchers []Fetcher
+}
+
+// GetBlock races GetBlock across all backends and returns the first
+// successful result, canceling the rest.
+func (f *multiFetcher) GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	if len(f.fetchers) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		blk blocks.Block
+		err error
+	}
+
+	results := make(chan result, len(f.fetchers))
+	for _, fetcher := range f.fetchers {
+		fetcher := fetcher
+		go func() {
+			blk, err := fetcher.GetBlock(ctx, c)
+			results <- result{blk, err}
+		}()
+	}
+
+	var lastErr error
+	for range f.fetchers {
+		r := <-results
+		if r.err == nil {
+			return r.blk, nil
+		}
+		lastErr = r.err
+	}
+	return nil, lastErr
+}
+
+// GetBlocks multiplexes GetBlocks results from every backend into a single
+// channel, de-duplicating blocks by CID.
+func (f *multiFetcher) GetBlocks(ctx context.Context, cids []cid.Cid) (<-chan blocks.Block, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		seen    = make(map[cid.Cid]struct{}, len(cids))
+		opened  int
+		lastErr error
+	)
+
+	out := make(chan blocks.Block)
+	for _, fetcher := range f.fetchers {
+		ch, err := fetcher.GetBlocks(ctx, cids)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		opened++
+
+		wg.Add(1)
+		go func(ch <-chan blocks.Block) {
+			defer wg.Done()
+			for blk := range ch {
+				mu.Lock()
+				_, dup := seen[blk.Cid()]
+				if !dup {
+					seen[blk.Cid()] = struct{}{}
+				}
+				mu.Unlock()
+				if dup {
+					continue
+				}
+				select {
+				case out <- blk:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	if opened == 0 {
+		if lastErr == nil {
+			// Every backend that returns without an error counts as opened,
+			// so this only happens when there were no backends to begin
+			// with; we must not report success with a nil channel.
+			lastErr = ErrNoBackends
+		}
+		return nil, lastErr
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// MultiExchange is a SessionExchange that fans out Fetcher calls across an
+// ordered list of backend exchanges, for example a local bitswap instance
+// plus one or more HTTP/trustless-gateway fetchers. GetBlock races all
+// backends and returns the first valid result; GetBlocks multiplexes and
+// de-duplicates results from every backend. NotifyNewBlocks and Close
+// broadcast to every backend and aggregate any errors.
+type MultiExchange struct {
+	*multiFetcher
+
+	backends []Interface
+}
+
+var _ SessionExchange = (*MultiExchange)(nil)
+
+// NewMultiExchange constructs a MultiExchange that fans out across the given
+// backends, in the order given.
+func NewMultiExchange(backends ...Interface) *MultiExchange {
+	fetchers := make([]Fetcher, len(backends))
+	for i, b := range backends {
+		fetchers[i] = b
+	}
+	return &MultiExchange{
+		multiFetcher: &multiFetcher{fetchers: fetchers},
+		backends:     backends,
+	}
+}
+
+// NotifyNewBlocks broadcasts the new blocks to every backend.
+func (m *MultiExchange) NotifyNewBlocks(ctx context.Context, blks ...blocks.Block) error {
+	errs := make([]error, 0, len(m.backends))
+	for _, b := range m.backends {
+		if err := b.NotifyNewBlocks(ctx, blks...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsOnline reports whether any backend is online.
+func (m *MultiExchange) IsOnline() bool {
+	for _, b := range m.backends {
+		if b.IsOnline() {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSession creates a per-backend session for every backend that supports
+// sessions, falling back to the backend itself for those that don't, and
+// bundles them into a single composite Fetcher. Backends that implement
+// AllowlistExchange have their allowlist applied to their session Fetcher, so
+// fetching through the composite can't bypass a backend's hash-security
+// checks.
+func (m *MultiExchange) NewSession(ctx context.Context) Fetcher {
+	fetchers := make([]Fetcher, len(m.backends))
+	for i, b := range m.backends {
+		var f Fetcher
+		if se, ok := b.(SessionExchange); ok {
+			f = se.NewSession(ctx)
+		} else {
+			f = b
+		}
+		if ae, ok := b.(AllowlistExchange); ok {
+			f = NewAllowlistSession(f, ae.Allowlist())
+		}
+		fetchers[i] = f
+	}
+	return &multiFetcher{fetchers: fetchers}
+}
+
+// Close closes every backend, aggregating any errors.
+func (m *MultiExchange) Close() error {
+	errs := make([]error, 0, len(m.backends))
+	for _, b := range m.backends {
+		if err := b.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,250 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	cid "github.com/ipfs/go-cid"
+	blocksutil "github.com/ipfs/go-ipfs-blocksutil"
+)
+
+// fakeExchange is a minimal in-memory Interface used to exercise
+// MultiExchange without a real bitswap/gateway backend.
+type fakeExchange struct {
+	mu     sync.Mutex
+	blocks map[cid.Cid]blocks.Block
+	delay  time.Duration
+	err    error
+	online bool
+	closed bool
+}
+
+func newFakeExchange(blks ...blocks.Block) *fakeExchange {
+	m := make(map[cid.Cid]blocks.Block, len(blks))
+	for _, b := range blks {
+		m[b.Cid()] = b
+	}
+	return &fakeExchange{blocks: m, online: true}
+}
+
+func (e *fakeExchange) GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	e.mu.Lock()
+	delay, err := e.delay, e.err
+	blk, ok := e.blocks[c]
+	e.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("fakeExchange: block not found")
+	}
+	return blk, nil
+}
+
+func (e *fakeExchange) GetBlocks(ctx context.Context, cids []cid.Cid) (<-chan blocks.Block, error) {
+	e.mu.Lock()
+	if e.err != nil {
+		e.mu.Unlock()
+		return nil, e.err
+	}
+	toSend := make([]blocks.Block, 0, len(cids))
+	for _, c := range cids {
+		if blk, ok := e.blocks[c]; ok {
+			toSend = append(toSend, blk)
+		}
+	}
+	delay := e.delay
+	e.mu.Unlock()
+
+	out := make(chan blocks.Block)
+	go func() {
+		defer close(out)
+		for _, blk := range toSend {
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- blk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (e *fakeExchange) NotifyNewBlocks(ctx context.Context, blks ...blocks.Block) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return e.err
+	}
+	for _, b := range blks {
+		e.blocks[b.Cid()] = b
+	}
+	return nil
+}
+
+func (e *fakeExchange) IsOnline() bool { return e.online }
+
+func (e *fakeExchange) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return e.err
+}
+
+var _ Interface = (*fakeExchange)(nil)
+
+func TestMultiExchangeGetBlockReturnsFirstSuccess(t *testing.T) {
+	bg := blocksutil.NewBlockGenerator()
+	blk := bg.Next()
+
+	slow := newFakeExchange()
+	slow.err = errors.New("slow backend miss")
+	slow.delay = 50 * time.Millisecond
+
+	fast := newFakeExchange(blk)
+
+	mx := NewMultiExchange(slow, fast)
+	got, err := mx.GetBlock(context.Background(), blk.Cid())
+	if err != nil {
+		t.Fatalf("GetBlock returned error: %v", err)
+	}
+	if got.Cid() != blk.Cid() {
+		t.Fatalf("GetBlock returned wrong block: got %v, want %v", got.Cid(), blk.Cid())
+	}
+}
+
+func TestMultiExchangeGetBlockAllMiss(t *testing.T) {
+	bg := blocksutil.NewBlockGenerator()
+	blk := bg.Next()
+
+	mx := NewMultiExchange(newFakeExchange(), newFakeExchange())
+	if _, err := mx.GetBlock(context.Background(), blk.Cid()); err == nil {
+		t.Fatal("expected an error when no backend has the block")
+	}
+}
+
+func TestMultiExchangeGetBlockNoBackends(t *testing.T) {
+	mx := NewMultiExchange()
+	bg := blocksutil.NewBlockGenerator()
+	if _, err := mx.GetBlock(context.Background(), bg.Next().Cid()); !errors.Is(err, ErrNoBackends) {
+		t.Fatalf("expected ErrNoBackends, got %v", err)
+	}
+}
+
+func TestMultiExchangeGetBlocksDeduplicates(t *testing.T) {
+	bg := blocksutil.NewBlockGenerator()
+	blks := bg.Blocks(3)
+
+	a := newFakeExchange(blks[0], blks[1])
+	b := newFakeExchange(blks[1], blks[2])
+
+	mx := NewMultiExchange(a, b)
+
+	cids := make([]cid.Cid, len(blks))
+	for i, blk := range blks {
+		cids[i] = blk.Cid()
+	}
+
+	ch, err := mx.GetBlocks(context.Background(), cids)
+	if err != nil {
+		t.Fatalf("GetBlocks returned error: %v", err)
+	}
+
+	seen := make(map[cid.Cid]int)
+	for blk := range ch {
+		seen[blk.Cid()]++
+	}
+	for _, blk := range blks {
+		if seen[blk.Cid()] != 1 {
+			t.Fatalf("block %v observed %d times, want 1", blk.Cid(), seen[blk.Cid()])
+		}
+	}
+}
+
+func TestMultiExchangeGetBlocksNoBackends(t *testing.T) {
+	mx := NewMultiExchange()
+	if _, err := mx.GetBlocks(context.Background(), nil); !errors.Is(err, ErrNoBackends) {
+		t.Fatalf("expected ErrNoBackends, got %v", err)
+	}
+}
+
+func TestMultiExchangeGetBlocksStopsOnContextCancel(t *testing.T) {
+	bg := blocksutil.NewBlockGenerator()
+	blks := bg.Blocks(5)
+
+	slow := newFakeExchange(blks...)
+	slow.delay = 50 * time.Millisecond
+
+	mx := NewMultiExchange(slow)
+
+	cids := make([]cid.Cid, len(blks))
+	for i, blk := range blks {
+		cids[i] = blk.Cid()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := mx.GetBlocks(ctx, cids)
+	if err != nil {
+		t.Fatalf("GetBlocks returned error: %v", err)
+	}
+
+	<-ch // wait for the first block so we know the fan-out goroutine is running
+	cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetBlocks channel did not close after context cancellation")
+	}
+}
+
+func TestMultiExchangeNotifyNewBlocksAndCloseAggregateErrors(t *testing.T) {
+	a := newFakeExchange()
+	a.err = errors.New("a broke")
+	b := newFakeExchange()
+	b.err = errors.New("b broke")
+
+	mx := NewMultiExchange(a, b)
+
+	bg := blocksutil.NewBlockGenerator()
+	blk := bg.Next()
+
+	err := mx.NotifyNewBlocks(context.Background(), blk)
+	if err == nil || !errors.Is(err, a.err) || !errors.Is(err, b.err) {
+		t.Fatalf("expected NotifyNewBlocks to aggregate both backend errors, got %v", err)
+	}
+
+	err = mx.Close()
+	if err == nil || !errors.Is(err, a.err) || !errors.Is(err, b.err) {
+		t.Fatalf("expected Close to aggregate both backend errors, got %v", err)
+	}
+	if !a.closed || !b.closed {
+		t.Fatal("expected Close to be called on every backend")
+	}
+}
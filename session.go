@@ -0,0 +1,177 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Session is a Fetcher scoped to a single exchange session. Exchanges that
+// want to track per-session state (peer affinity, wantlist scoping,
+// telemetry, ...) can return a Session from NewSession instead of a bare
+// Fetcher.
+type Session interface {
+	Fetcher
+
+	// ID returns the SessionID identifying this session.
+	ID() SessionID
+
+	// Close releases any resources held by the session. It is safe to call
+	// Close more than once, and Close is called automatically when the
+	// session's context is canceled.
+	Close() error
+}
+
+type sessionContextKey struct{}
+type sessionContextValue struct {
+	sesID  SessionID
+	sesCtx context.Context
+}
+
+// NewSession registers a new session with the context. The session will be
+// closed when the passed-in context is canceled.
+//
+// If there's already a session associated with the context, the existing
+// session will be used.
+//
+// This function does not initialize any state, it just reserves a new
+// SessionID and associates it with the context. Exchanges that need
+// lifecycle hooks or a live-session registry should use a SessionManager
+// instead, which is opt-in and does not affect this function.
+func NewSession(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
+		return ctx
+	}
+	_, ctx = createSession(ctx)
+	return ctx
+}
+
+// last allocated session ID. 0 is _never_ used.
+var lastSessionID uint64
+
+// GetOrCreateSession loads the session from the context, or creates one if
+// there is no associated session.
+//
+// This function also returns the context used to create the session. The
+// session should be stopped when this context is canceled.
+func GetOrCreateSession(ctx context.Context) (SessionID, context.Context) {
+	if s, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
+		return s.sesID, s.sesCtx
+	}
+	return createSession(ctx)
+}
+
+func createSession(ctx context.Context) (SessionID, context.Context) {
+	// Allocate a new session ID
+	id := SessionID{atomic.AddUint64(&lastSessionID, 1)}
+
+	// Create a spot to hold the session information.
+	ctxValue := &sessionContextValue{sesID: id}
+
+	// Derive a new context with this information.
+	ctx = context.WithValue(ctx, sessionContextKey{}, ctxValue)
+
+	// Cyclically reference the session context so the session's context
+	// also references the session.
+	//
+	// We could reference the original context, but that doesn't have the
+	// session attached to it.
+	ctxValue.sesCtx = ctx
+
+	return id, ctx
+}
+
+// SessionManager is an opt-in registry of live sessions that notifies
+// registered hooks as sessions start and end. Unlike the package-level
+// NewSession/GetOrCreateSession helpers above, constructing a SessionManager
+// is a deliberate choice: only exchanges that want the registry and its
+// per-session goroutine pay for them. The zero value is ready to use;
+// NewSessionManager returns one.
+type SessionManager struct {
+	mu      sync.Mutex
+	onStart []func(SessionID, context.Context)
+	onEnd   []func(SessionID)
+
+	sessions sync.Map // SessionID -> context.Context
+}
+
+// NewSessionManager constructs an empty SessionManager.
+func NewSessionManager() *SessionManager {
+	return &SessionManager{}
+}
+
+// OnSessionStart registers a hook that runs synchronously whenever this
+// manager creates a new session.
+func (m *SessionManager) OnSessionStart(hook func(SessionID, context.Context)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onStart = append(m.onStart, hook)
+}
+
+// OnSessionEnd registers a hook that runs whenever a session created by this
+// manager has its context canceled. End hooks run on the session's cleanup
+// goroutine, after the session has been removed from the manager, not on the
+// goroutine that canceled the context.
+func (m *SessionManager) OnSessionEnd(hook func(SessionID)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEnd = append(m.onEnd, hook)
+}
+
+// LookupSession returns the context associated with the given SessionID, if
+// the session is still live. This is meant for out-of-band callers, such as
+// metrics dumps or admin endpoints, that only have the ID to go on.
+func (m *SessionManager) LookupSession(id SessionID) (context.Context, bool) {
+	v, ok := m.sessions.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(context.Context), true
+}
+
+// NewSession behaves like the package-level NewSession, except the session
+// is also registered with this manager: start hooks fire immediately, and a
+// goroutine removes the session and fires end hooks once ctx is canceled.
+func (m *SessionManager) NewSession(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
+		return ctx
+	}
+	_, ctx = m.createSession(ctx)
+	return ctx
+}
+
+// GetOrCreateSession behaves like the package-level GetOrCreateSession,
+// registering any newly created session with this manager.
+func (m *SessionManager) GetOrCreateSession(ctx context.Context) (SessionID, context.Context) {
+	if s, ok := ctx.Value(sessionContextKey{}).(*sessionContextValue); ok {
+		return s.sesID, s.sesCtx
+	}
+	return m.createSession(ctx)
+}
+
+func (m *SessionManager) createSession(ctx context.Context) (SessionID, context.Context) {
+	id, ctx := createSession(ctx)
+
+	m.sessions.Store(id, ctx)
+
+	m.mu.Lock()
+	onStart := append([]func(SessionID, context.Context){}, m.onStart...)
+	m.mu.Unlock()
+	for _, hook := range onStart {
+		hook(id, ctx)
+	}
+
+	go func() {
+		<-ctx.Done()
+		m.sessions.Delete(id)
+
+		m.mu.Lock()
+		onEnd := append([]func(SessionID){}, m.onEnd...)
+		m.mu.Unlock()
+		for _, hook := range onEnd {
+			hook(id)
+		}
+	}()
+
+	return id, ctx
+}
@@ -0,0 +1,92 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionManagerHooksAndCleanup(t *testing.T) {
+	m := NewSessionManager()
+
+	started := make(chan SessionID, 1)
+	ended := make(chan SessionID, 1)
+	m.OnSessionStart(func(id SessionID, _ context.Context) { started <- id })
+	m.OnSessionEnd(func(id SessionID) { ended <- id })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id, sesCtx := m.GetOrCreateSession(ctx)
+	if id.IsZero() {
+		t.Fatal("expected a non-zero session ID")
+	}
+
+	select {
+	case startedID := <-started:
+		if startedID != id {
+			t.Fatalf("OnSessionStart fired with %v, want %v", startedID, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnSessionStart hook did not fire")
+	}
+
+	if got, ok := m.LookupSession(id); !ok || got != sesCtx {
+		t.Fatal("LookupSession did not return the live session context")
+	}
+
+	cancel()
+
+	select {
+	case endedID := <-ended:
+		if endedID != id {
+			t.Fatalf("OnSessionEnd fired with %v, want %v", endedID, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnSessionEnd hook did not fire after context cancellation")
+	}
+
+	// The cleanup goroutine removes the entry right before firing the end
+	// hook, so by the time we observe the hook LookupSession should miss.
+	if _, ok := m.LookupSession(id); ok {
+		t.Fatal("expected session to be removed from the manager after cancellation")
+	}
+}
+
+func TestSessionManagerGetOrCreateSessionReusesExisting(t *testing.T) {
+	m := NewSessionManager()
+
+	var starts int
+	m.OnSessionStart(func(SessionID, context.Context) { starts++ })
+
+	ctx := context.Background()
+	id1, ctx1 := m.GetOrCreateSession(ctx)
+	id2, ctx2 := m.GetOrCreateSession(ctx1)
+
+	if id1 != id2 {
+		t.Fatalf("expected the same session ID on reuse, got %v and %v", id1, id2)
+	}
+	if ctx2 != ctx1 {
+		t.Fatal("expected GetOrCreateSession to return the existing session context unchanged")
+	}
+	if starts != 1 {
+		t.Fatalf("expected exactly one session start, got %d", starts)
+	}
+}
+
+func TestNewSessionDoesNotRegisterWithAnyManager(t *testing.T) {
+	m := NewSessionManager()
+
+	var starts int
+	m.OnSessionStart(func(SessionID, context.Context) { starts++ })
+
+	ctx := NewSession(context.Background())
+	id, _ := GetOrCreateSession(ctx)
+	if id.IsZero() {
+		t.Fatal("expected a non-zero session ID")
+	}
+	if starts != 0 {
+		t.Fatal("package-level NewSession must not notify unrelated SessionManagers")
+	}
+	if _, ok := m.LookupSession(id); ok {
+		t.Fatal("package-level NewSession must not register with unrelated SessionManagers")
+	}
+}